@@ -0,0 +1,45 @@
+package robadger
+
+import (
+	"errors"
+)
+
+// ErrKeyNotFound is returned by a KVBackend's Get when the key is absent.
+// Every KVBackend implementation MUST return it in that case - callers
+// written against one backend (e.g. the default Badger one) should see the
+// same behavior after swapping in another.
+var ErrKeyNotFound = errors.New("robadger: key not found")
+
+// ErrConflict is returned by a KVBackend's Batch when it detects a
+// write-write conflict and the caller should retry the transaction.
+var ErrConflict = errors.New("robadger: transaction conflict, retry")
+
+// Entry is a single key/value pair, used when writing many keys at once.
+type Entry struct {
+	Key []byte
+	Val []byte
+}
+
+// KVBackend is the storage interface Store delegates to. Store layers
+// convenience methods (typed values, transactions, iteration, ...) on top,
+// while a KVBackend only has to know how to get, set, and enumerate bytes.
+type KVBackend interface {
+	// Open prepares the backend for use (opening files, connecting, etc).
+	Open() error
+	// Close releases any resources held by the backend.
+	Close() error
+
+	Get(key []byte) ([]byte, error)
+	Set(key, val []byte) error
+	Exists(key []byte) (bool, error)
+	// Touch creates key with an empty value if it doesn't already exist.
+	Touch(key []byte) error
+	Delete(key []byte) error
+
+	// Iterate calls fn for every key matching prefix (nil matches all keys).
+	Iterate(prefix []byte, fn func(key, val []byte) error) error
+	// Batch writes all entries as a single operation where the backend
+	// supports it, returning ErrConflict if a write-write conflict was
+	// detected and the caller should retry.
+	Batch(entries []Entry) error
+}
@@ -0,0 +1,360 @@
+package robadger
+
+import (
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rohanthewiz/serr"
+)
+
+// badgerBackend is the default on-disk KVBackend, backed by BadgerDB.
+type badgerBackend struct {
+	db      *badger.DB
+	dirs    []string
+	tempDir string
+	gcRuns  int64
+}
+
+// NewBadgerBackend returns a KVBackend backed by BadgerDB.
+// If no directories are supplied, a temp directory will be created i.e. data is volatile!
+// If only one directory is supplied, it is used as the key and value stores
+// If more than 1 directory is supplied, the first will be used as the key store, and the second as the value store
+func NewBadgerBackend(dirs ...string) KVBackend {
+	return &badgerBackend{dirs: dirs}
+}
+
+func (b *badgerBackend) Open() error {
+	var dir, valueDir string
+	switch len(b.dirs) {
+	case 0:
+		tmpDir, err := ioutil.TempDir("/tmp", "badger")
+		if err != nil {
+			return serr.Wrap(err, "Error creating temporary directory for badger store")
+		}
+		b.tempDir = tmpDir
+		dir, valueDir = tmpDir, tmpDir
+	case 1:
+		dir, valueDir = b.dirs[0], b.dirs[0]
+	default:
+		dir, valueDir = b.dirs[0], b.dirs[1]
+	}
+
+	bopt := badger.DefaultOptions(dir).WithValueDir(valueDir)
+	db, err := badger.Open(bopt)
+	if err != nil {
+		return serr.Wrap(err, "Unable to create a badger key-value store")
+	}
+	b.db = db
+	return nil
+}
+
+// Close closes the underlying badger store. Note that some data is flushed
+// on close, so the returned error should be checked. Example:
+//		defer func() {
+//			err = store.Close()
+//			if err != nil {
+//				Log("Error", "Error closing store - some values may not have been saved", "error", err.Error())
+//		}()
+func (b *badgerBackend) Close() error {
+	err := b.db.Close()
+	if err != nil {
+		return err
+	}
+	if b.tempDir != "" {
+		err = os.RemoveAll(b.tempDir) // be a good citizen
+	}
+	return err
+}
+
+// Get returns ErrKeyNotFound if key is absent, matching every other
+// KVBackend implementation - see the KVBackend doc comment for the
+// not-found contract.
+func (b *badgerBackend) Get(key []byte) ([]byte, error) {
+	var out []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return ErrKeyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			out = append([]byte(nil), val...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (b *badgerBackend) Set(key, val []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, val)
+	})
+}
+
+func (b *badgerBackend) Exists(key []byte) (bool, error) {
+	exists := false
+	err := b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		exists = true
+		return nil
+	})
+	return exists, err
+}
+
+func (b *badgerBackend) Touch(key []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		if err == nil {
+			return nil
+		}
+		if err != badger.ErrKeyNotFound {
+			return err
+		}
+		return txn.Set(key, []byte{})
+	})
+}
+
+func (b *badgerBackend) Delete(key []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (b *badgerBackend) Iterate(prefix []byte, fn func(key, val []byte) error) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		itr := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer itr.Close()
+
+		for itr.Seek(prefix); itr.ValidForPrefix(prefix); itr.Next() {
+			item := itr.Item()
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := fn(item.KeyCopy(nil), val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// NewIterator returns a native streaming cursor over the badger store,
+// honoring opts' Reverse, PrefetchSize, and KeysOnly settings.
+func (b *badgerBackend) NewIterator(opts IterOptions) *Iter {
+	prefetchSize := opts.PrefetchSize
+	if prefetchSize == 0 {
+		prefetchSize = DefaultIterOptions.PrefetchSize
+	}
+	bopts := badger.IteratorOptions{
+		PrefetchSize:   prefetchSize,
+		PrefetchValues: !opts.KeysOnly,
+		Reverse:        opts.Reverse,
+	}
+	txn := b.db.NewTransaction(false)
+	itr := txn.NewIterator(bopts)
+
+	// A reverse prefix scan must start past the end of the prefix range -
+	// Seek(prefix) lands on the largest key <= prefix, which is at or
+	// before the range, so ValidForPrefix would immediately be false.
+	// prefixSuccessor gives the exclusive upper bound of the range; if the
+	// prefix has no finite successor (e.g. it's all 0xFF, or empty),
+	// Rewind to the end of the keyspace instead - see its doc comment for
+	// why that's still correct.
+	seek := opts.Prefix
+	rewind := false
+	if opts.Reverse {
+		if succ := prefixSuccessor(opts.Prefix); succ != nil {
+			seek = succ
+		} else {
+			rewind = true
+		}
+	}
+
+	started := false
+	valid := func() bool {
+		if opts.Prefix == nil {
+			return itr.Valid()
+		}
+		return itr.ValidForPrefix(opts.Prefix)
+	}
+
+	return &Iter{
+		nextFn: func() bool {
+			if !started {
+				started = true
+				if rewind {
+					itr.Rewind()
+				} else {
+					itr.Seek(seek)
+					// A reverse Seek lands on seek itself when that key
+					// exists; step past it, as it lies outside the range.
+					if opts.Reverse && itr.Valid() && !valid() {
+						itr.Next()
+					}
+				}
+			} else {
+				itr.Next()
+			}
+			return valid()
+		},
+		keyFn: func() []byte {
+			if !valid() {
+				return nil
+			}
+			return itr.Item().KeyCopy(nil)
+		},
+		valFn: func() []byte {
+			if opts.KeysOnly || !valid() {
+				return nil
+			}
+			val, _ := itr.Item().ValueCopy(nil)
+			return val
+		},
+		closeFn: func() {
+			itr.Close()
+			txn.Discard()
+		},
+	}
+}
+
+func (b *badgerBackend) Batch(entries []Entry) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, e := range entries {
+		if err := wb.Set(e.Key, e.Val); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// SetWithTTL stores val under key, expiring it after ttl via Badger's own
+// entry expiration.
+func (b *badgerBackend) SetWithTTL(key, val []byte, ttl time.Duration) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry(key, val).WithTTL(ttl))
+	})
+}
+
+// RunValueLogGC reclaims space left behind by overwritten or deleted keys.
+func (b *badgerBackend) RunValueLogGC(discardRatio float64) error {
+	err := b.db.RunValueLogGC(discardRatio)
+	atomic.AddInt64(&b.gcRuns, 1)
+	return err
+}
+
+// Stats returns the on-disk LSM/value-log sizes and the number of GC runs
+// performed via RunValueLogGC.
+func (b *badgerBackend) Stats() (Stats, error) {
+	lsm, vlog := b.db.Size()
+	return Stats{
+		LSMSize:  lsm,
+		VLogSize: vlog,
+		GCRuns:   atomic.LoadInt64(&b.gcRuns),
+	}, nil
+}
+
+// badgerTxOps adapts a *badger.Txn to the txOps interface Tx delegates to,
+// giving Store.Update/View real snapshot isolation and conflict detection.
+type badgerTxOps struct {
+	txn *badger.Txn
+}
+
+func (o *badgerTxOps) Get(key []byte) ([]byte, error) {
+	item, err := o.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	err = item.Value(func(val []byte) error {
+		out = append([]byte(nil), val...)
+		return nil
+	})
+	return out, err
+}
+
+func (o *badgerTxOps) Set(key, val []byte) error {
+	return o.txn.Set(key, val)
+}
+
+func (o *badgerTxOps) Delete(key []byte) error {
+	return o.txn.Delete(key)
+}
+
+func (o *badgerTxOps) Exists(key []byte) (bool, error) {
+	_, err := o.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// runUpdate runs fn against a real badger.Txn, committing its writes when fn
+// returns nil. If the commit fails with badger.ErrConflict, fn is retried
+// from scratch with exponential backoff, for up to maxAttempts attempts in
+// all; any other
+// error (fn's own, or a commit failure) is returned immediately.
+func (b *badgerBackend) runUpdate(maxAttempts int, fn func(tx *Tx) error) error {
+	backoff := 10 * time.Millisecond
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err := b.db.Update(func(txn *badger.Txn) error {
+			return fn(&Tx{ops: &badgerTxOps{txn: txn}})
+		})
+		if err == nil {
+			return nil
+		}
+		if err != badger.ErrConflict {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return ErrConflict
+}
+
+// runView runs fn against a read-only badger.Txn, giving it a consistent
+// point-in-time snapshot of the store.
+func (b *badgerBackend) runView(fn func(tx *Tx) error) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		return fn(&Tx{ops: &badgerTxOps{txn: txn}, readOnly: true})
+	})
+}
+
+// prefixSuccessor returns the smallest key that sorts strictly after every
+// key with the given prefix, by incrementing the last byte that isn't
+// already 0xFF and truncating after it (e.g. "ab" -> "ac", "ab\xff" ->
+// "ac"). It returns nil if prefix is empty or consists entirely of 0xFF
+// bytes, since no finite key sorts after every such key - callers should
+// fall back to iterating from the very end of the keyspace instead.
+func prefixSuccessor(prefix []byte) []byte {
+	succ := append([]byte(nil), prefix...)
+	for i := len(succ) - 1; i >= 0; i-- {
+		if succ[i] != 0xFF {
+			succ[i]++
+			return succ[:i+1]
+		}
+	}
+	return nil
+}
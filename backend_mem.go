@@ -0,0 +1,156 @@
+package robadger
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// memBackend is an in-memory KVBackend, primarily useful for tests and other
+// ephemeral contexts where persistence isn't needed or wanted.
+type memBackend struct {
+	mu      sync.RWMutex
+	data    map[string][]byte
+	expires map[string]time.Time
+}
+
+// NewMemBackend returns a KVBackend that keeps everything in an in-memory map.
+func NewMemBackend() KVBackend {
+	return &memBackend{}
+}
+
+func (m *memBackend) Open() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string][]byte)
+	m.expires = make(map[string]time.Time)
+	return nil
+}
+
+func (m *memBackend) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = nil
+	m.expires = nil
+	return nil
+}
+
+// expiredLocked reports whether key has an expiry in the past. Callers must
+// hold m.mu.
+func (m *memBackend) expiredLocked(key string) bool {
+	exp, ok := m.expires[key]
+	return ok && time.Now().After(exp)
+}
+
+// purgeIfExpiredLocked deletes key's data and expiry if it has expired, so
+// it doesn't linger forever once it's past its TTL. Callers must hold m.mu
+// for writing.
+func (m *memBackend) purgeIfExpiredLocked(key string) {
+	if m.expiredLocked(key) {
+		delete(m.data, key)
+		delete(m.expires, key)
+	}
+}
+
+func (m *memBackend) Get(key []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := string(key)
+	m.purgeIfExpiredLocked(k)
+	val, ok := m.data[k]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	out := make([]byte, len(val))
+	copy(out, val)
+	return out, nil
+}
+
+// SetWithTTL stores val under key, expiring it after ttl.
+func (m *memBackend) SetWithTTL(key, val []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp := make([]byte, len(val))
+	copy(cp, val)
+	k := string(key)
+	m.data[k] = cp
+	m.expires[k] = time.Now().Add(ttl)
+	return nil
+}
+
+func (m *memBackend) Set(key, val []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp := make([]byte, len(val))
+	copy(cp, val)
+	k := string(key)
+	m.data[k] = cp
+	delete(m.expires, k)
+	return nil
+}
+
+func (m *memBackend) Exists(key []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := string(key)
+	m.purgeIfExpiredLocked(k)
+	_, ok := m.data[k]
+	return ok, nil
+}
+
+// Touch creates key with an empty, TTL-less value if it's absent or has
+// expired; it leaves an existing, unexpired key (and its TTL) untouched.
+func (m *memBackend) Touch(key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := string(key)
+	m.purgeIfExpiredLocked(k)
+	if _, ok := m.data[k]; !ok {
+		m.data[k] = []byte{}
+		delete(m.expires, k)
+	}
+	return nil
+}
+
+func (m *memBackend) Delete(key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, string(key))
+	return nil
+}
+
+func (m *memBackend) Iterate(prefix []byte, fn func(key, val []byte) error) error {
+	type pair struct{ k, v []byte }
+
+	m.mu.RLock()
+	matches := make([]pair, 0)
+	for k, v := range m.data {
+		if strings.HasPrefix(k, string(prefix)) && !m.expiredLocked(k) {
+			matches = append(matches, pair{[]byte(k), v})
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, p := range matches {
+		if err := fn(p.k, p.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memBackend) Batch(entries []Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range entries {
+		cp := make([]byte, len(e.Val))
+		copy(cp, e.Val)
+		k := string(e.Key)
+		m.data[k] = cp
+		delete(m.expires, k)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package robadger
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/rohanthewiz/serr"
+)
+
+// redisBackend is a KVBackend backed by a Redis server, useful when several
+// processes need to share one store instead of each keeping a local file.
+type redisBackend struct {
+	opt    *redis.Options
+	client *redis.Client
+}
+
+// NewRedisBackend returns a KVBackend backed by the Redis server described by opt.
+func NewRedisBackend(opt *redis.Options) KVBackend {
+	return &redisBackend{opt: opt}
+}
+
+func (r *redisBackend) Open() error {
+	r.client = redis.NewClient(r.opt)
+	if err := r.client.Ping().Err(); err != nil {
+		return serr.Wrap(err, "Unable to connect to redis store")
+	}
+	return nil
+}
+
+func (r *redisBackend) Close() error {
+	return r.client.Close()
+}
+
+func (r *redisBackend) Get(key []byte) ([]byte, error) {
+	val, err := r.client.Get(string(key)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, serr.Wrap(err, "Error getting key from redis")
+	}
+	return val, nil
+}
+
+func (r *redisBackend) Set(key, val []byte) error {
+	if err := r.client.Set(string(key), val, 0).Err(); err != nil {
+		return serr.Wrap(err, "Error setting key in redis")
+	}
+	return nil
+}
+
+// SetWithTTL stores val under key, expiring it after ttl using Redis' own
+// native key expiration.
+func (r *redisBackend) SetWithTTL(key, val []byte, ttl time.Duration) error {
+	if err := r.client.Set(string(key), val, ttl).Err(); err != nil {
+		return serr.Wrap(err, "Error setting key with TTL in redis")
+	}
+	return nil
+}
+
+func (r *redisBackend) Exists(key []byte) (bool, error) {
+	n, err := r.client.Exists(string(key)).Result()
+	if err != nil {
+		return false, serr.Wrap(err, "Error checking key existence in redis")
+	}
+	return n > 0, nil
+}
+
+func (r *redisBackend) Touch(key []byte) error {
+	exists, err := r.Exists(key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return r.Set(key, []byte{})
+}
+
+func (r *redisBackend) Delete(key []byte) error {
+	return r.client.Del(string(key)).Err()
+}
+
+func (r *redisBackend) Iterate(prefix []byte, fn func(key, val []byte) error) error {
+	// Escape glob metacharacters so prefix is matched literally.
+	pattern := make([]byte, 0, len(prefix)+1)
+	for _, c := range prefix {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			pattern = append(pattern, '\\')
+		}
+		pattern = append(pattern, c)
+	}
+	iter := r.client.Scan(0, string(pattern)+"*", 0).Iterator()
+	for iter.Next() {
+		key := iter.Val()
+		val, err := r.client.Get(key).Bytes()
+		if err != nil && err != redis.Nil {
+			return serr.Wrap(err, "Error reading key during redis scan")
+		}
+		if err := fn([]byte(key), val); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (r *redisBackend) Batch(entries []Entry) error {
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = string(e.Key)
+	}
+
+	err := r.client.Watch(func(tx *redis.Tx) error {
+		_, err := tx.Pipelined(func(pipe redis.Pipeliner) error {
+			for _, e := range entries {
+				pipe.Set(string(e.Key), e.Val, 0)
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+
+	if err == redis.TxFailedErr {
+		return ErrConflict
+	}
+	if err != nil {
+		return serr.Wrap(err, "Error executing redis batch transaction")
+	}
+	return nil
+}
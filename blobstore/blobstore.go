@@ -0,0 +1,230 @@
+// Package blobstore layers a content-addressable blob API on top of a
+// robadger.Store, turning it into a general-purpose object store rather
+// than a plain K/V wrapper.
+package blobstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/rohanthewiz/robadger"
+	"github.com/rohanthewiz/serr"
+)
+
+// ID is the content address of a blob: the hex-encoded SHA-256 of its bytes.
+type ID string
+
+const (
+	blobPrefix = "blob:"
+	refPrefix  = "ref:"
+
+	// DefaultChunkSize is used when a BlobStore is created without an
+	// explicit chunk size.
+	DefaultChunkSize = 4 << 20 // 4MB
+
+	tagRaw      byte = 0
+	tagManifest byte = 1
+)
+
+// ErrBlobNotFound is returned when a blob ID has no content stored for it.
+var ErrBlobNotFound = errors.New("blobstore: blob not found")
+
+// ErrRefNotFound is returned when a ref name has no blob linked to it.
+var ErrRefNotFound = errors.New("blobstore: ref not found")
+
+// manifest describes how a blob larger than one chunk was split on write.
+type manifest struct {
+	Size      int64
+	ChunkSize int
+	NumChunks int
+}
+
+// BlobStore layers a content-addressable object API over a robadger.Store.
+type BlobStore struct {
+	store     *robadger.Store
+	chunkSize int
+}
+
+// New creates a BlobStore on top of an existing robadger.Store, splitting
+// any blob larger than DefaultChunkSize into chunks on write.
+func New(store *robadger.Store) *BlobStore {
+	return NewWithChunkSize(store, DefaultChunkSize)
+}
+
+// NewWithChunkSize creates a BlobStore with a custom chunk size for large blobs.
+func NewWithChunkSize(store *robadger.Store, chunkSize int) *BlobStore {
+	return &BlobStore{store: store, chunkSize: chunkSize}
+}
+
+// PutBlob reads all of r, stores it under its content hash, and returns the
+// resulting ID. Writing the same content twice is a no-op the second time.
+func (bs *BlobStore) PutBlob(r io.Reader) (ID, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", serr.Wrap(err, "Error reading blob content")
+	}
+	sum := sha256.Sum256(data)
+	id := ID(hex.EncodeToString(sum[:]))
+
+	has, err := bs.HasBlob(id)
+	if err != nil {
+		return "", err
+	}
+	if has {
+		return id, nil
+	}
+
+	if len(data) <= bs.chunkSize {
+		val := make([]byte, 1+len(data))
+		val[0] = tagRaw
+		copy(val[1:], data)
+		if err := bs.store.SetBytes(blobKey(id), val); err != nil {
+			return "", serr.Wrap(err, "Error writing blob")
+		}
+		return id, nil
+	}
+
+	numChunks := (len(data) + bs.chunkSize - 1) / bs.chunkSize
+	for n := 0; n < numChunks; n++ {
+		start := n * bs.chunkSize
+		end := start + bs.chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := bs.store.SetBytes(chunkKey(id, n), data[start:end]); err != nil {
+			return "", serr.Wrap(err, "Error writing blob chunk")
+		}
+	}
+
+	m := encodeManifest(manifest{Size: int64(len(data)), ChunkSize: bs.chunkSize, NumChunks: numChunks})
+	if err := bs.store.SetBytes(blobKey(id), m); err != nil {
+		return "", serr.Wrap(err, "Error writing blob manifest")
+	}
+	return id, nil
+}
+
+// GetBlob returns a reader over the content stored under id. The caller
+// must Close the returned reader.
+func (bs *BlobStore) GetBlob(id ID) (io.ReadCloser, error) {
+	raw, err := bs.store.GetBytes(blobKey(id))
+	if errors.Is(err, robadger.ErrKeyNotFound) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, serr.Wrap(err, "Error reading blob")
+	}
+
+	switch raw[0] {
+	case tagRaw:
+		return ioutil.NopCloser(bytes.NewReader(raw[1:])), nil
+	case tagManifest:
+		m, err := decodeManifest(raw[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 0, m.Size)
+		for n := 0; n < m.NumChunks; n++ {
+			chunk, err := bs.store.GetBytes(chunkKey(id, n))
+			if err != nil {
+				return nil, serr.Wrap(err, "Error reading blob chunk")
+			}
+			buf = append(buf, chunk...)
+		}
+		return ioutil.NopCloser(bytes.NewReader(buf)), nil
+	default:
+		return nil, errors.New("blobstore: unrecognized blob encoding")
+	}
+}
+
+// HasBlob reports whether content is stored under id.
+func (bs *BlobStore) HasBlob(id ID) (bool, error) {
+	exists, err := bs.store.Exists(blobKey(id))
+	if err != nil {
+		return false, serr.Wrap(err, "Error checking blob existence")
+	}
+	return exists, nil
+}
+
+// DeleteBlob removes the content stored under id, including any chunks.
+func (bs *BlobStore) DeleteBlob(id ID) error {
+	raw, err := bs.store.GetBytes(blobKey(id))
+	if errors.Is(err, robadger.ErrKeyNotFound) {
+		return ErrBlobNotFound
+	}
+	if err != nil {
+		return serr.Wrap(err, "Error reading blob before delete")
+	}
+	if len(raw) > 0 && raw[0] == tagManifest {
+		m, err := decodeManifest(raw[1:])
+		if err != nil {
+			return err
+		}
+		for n := 0; n < m.NumChunks; n++ {
+			if err := bs.store.Delete(chunkKey(id, n)); err != nil {
+				return serr.Wrap(err, "Error deleting blob chunk")
+			}
+		}
+	}
+	return bs.store.Delete(blobKey(id))
+}
+
+// Link gives id the symbolic name name, so it can later be looked up with
+// Resolve instead of by its hash.
+func (bs *BlobStore) Link(name string, id ID) error {
+	return bs.store.SetBytes(refKey(name), []byte(id))
+}
+
+// Resolve returns the blob ID linked to name.
+func (bs *BlobStore) Resolve(name string) (ID, error) {
+	val, err := bs.store.GetBytes(refKey(name))
+	if errors.Is(err, robadger.ErrKeyNotFound) {
+		return "", ErrRefNotFound
+	}
+	if err != nil {
+		return "", serr.Wrap(err, "Error resolving ref")
+	}
+	return ID(val), nil
+}
+
+// Unlink removes the symbolic name name. The underlying blob is untouched.
+func (bs *BlobStore) Unlink(name string) error {
+	return bs.store.Delete(refKey(name))
+}
+
+func blobKey(id ID) []byte {
+	return []byte(blobPrefix + string(id))
+}
+
+func chunkKey(id ID, n int) []byte {
+	return []byte(fmt.Sprintf("%s%s:%d", blobPrefix, id, n))
+}
+
+func refKey(name string) []byte {
+	return []byte(refPrefix + name)
+}
+
+func encodeManifest(m manifest) []byte {
+	buf := make([]byte, 1+8+4+4)
+	buf[0] = tagManifest
+	binary.BigEndian.PutUint64(buf[1:9], uint64(m.Size))
+	binary.BigEndian.PutUint32(buf[9:13], uint32(m.ChunkSize))
+	binary.BigEndian.PutUint32(buf[13:17], uint32(m.NumChunks))
+	return buf
+}
+
+func decodeManifest(b []byte) (manifest, error) {
+	if len(b) < 16 {
+		return manifest{}, errors.New("blobstore: corrupt manifest")
+	}
+	return manifest{
+		Size:      int64(binary.BigEndian.Uint64(b[0:8])),
+		ChunkSize: int(binary.BigEndian.Uint32(b[8:12])),
+		NumChunks: int(binary.BigEndian.Uint32(b[12:16])),
+	}, nil
+}
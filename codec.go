@@ -0,0 +1,130 @@
+package robadger
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/vmihailenco/msgpack"
+)
+
+// ErrCodecMismatch is returned when the value stored under a key was
+// written with a different codec than the one being used to read it.
+var ErrCodecMismatch = errors.New("robadger: value was stored with a different codec")
+
+// Codec marshals and unmarshals values for typed storage. ContentType
+// returns a one-byte tag that's stored alongside the encoded value so a
+// later Get using a different codec can be detected as a mismatch.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	ContentType() byte
+}
+
+const (
+	codecJSON    byte = 1
+	codecGob     byte = 2
+	codecMsgpack byte = 3
+)
+
+// JSONCodec encodes values as JSON.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (JSONCodec) ContentType() byte                          { return codecJSON }
+
+// GobCodec encodes values with encoding/gob.
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (GobCodec) ContentType() byte { return codecGob }
+
+// MsgpackCodec encodes values as MessagePack.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (MsgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+func (MsgpackCodec) ContentType() byte                          { return codecMsgpack }
+
+func (s *Store) setWithCodec(key string, v interface{}, c Codec) error {
+	data, err := c.Marshal(v)
+	if err != nil {
+		return serr.Wrap(err, "Error marshaling value")
+	}
+	val := make([]byte, 1+len(data))
+	val[0] = c.ContentType()
+	copy(val[1:], data)
+	return s.backend.Set([]byte(key), val)
+}
+
+func (s *Store) getWithCodec(key string, v interface{}, c Codec) error {
+	raw, err := s.backend.Get([]byte(key))
+	if err == ErrKeyNotFound {
+		return ErrKeyNotFound
+	}
+	if err != nil {
+		return serr.Wrap(err, "Error getting value")
+	}
+	if raw[0] != c.ContentType() {
+		return ErrCodecMismatch
+	}
+	return c.Unmarshal(raw[1:], v)
+}
+
+// SetJSON marshals v as JSON and stores it under key.
+func (s *Store) SetJSON(key string, v interface{}) error {
+	return s.setWithCodec(key, v, JSONCodec{})
+}
+
+// GetJSON unmarshals the JSON value stored under key into v.
+func (s *Store) GetJSON(key string, v interface{}) error {
+	return s.getWithCodec(key, v, JSONCodec{})
+}
+
+// SetGob encodes v with encoding/gob and stores it under key.
+func (s *Store) SetGob(key string, v interface{}) error {
+	return s.setWithCodec(key, v, GobCodec{})
+}
+
+// GetGob decodes the gob value stored under key into v.
+func (s *Store) GetGob(key string, v interface{}) error {
+	return s.getWithCodec(key, v, GobCodec{})
+}
+
+// SetMsgpack encodes v as MessagePack and stores it under key.
+func (s *Store) SetMsgpack(key string, v interface{}) error {
+	return s.setWithCodec(key, v, MsgpackCodec{})
+}
+
+// GetMsgpack decodes the MessagePack value stored under key into v.
+func (s *Store) GetMsgpack(key string, v interface{}) error {
+	return s.getWithCodec(key, v, MsgpackCodec{})
+}
+
+// SetTyped marshals v with codec c and stores it under key, tagging the
+// value so GetTyped can detect a codec mismatch on read.
+func SetTyped[T any](s *Store, key string, v T, c Codec) error {
+	return s.setWithCodec(key, v, c)
+}
+
+// GetTyped reads the value stored under key and unmarshals it with codec c
+// into a new T. It returns ErrCodecMismatch if the value was stored with a
+// different codec.
+func GetTyped[T any](s *Store, key string, c Codec) (out T, err error) {
+	err = s.getWithCodec(key, &out, c)
+	return
+}
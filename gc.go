@@ -0,0 +1,109 @@
+package robadger
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// ErrTTLUnsupported is returned by SetWithTTL when the backend doesn't support expiring values.
+var ErrTTLUnsupported = errors.New("robadger: backend does not support TTL")
+
+// ErrStatsUnsupported is returned by Stats when the backend doesn't expose storage metrics.
+var ErrStatsUnsupported = errors.New("robadger: backend does not support Stats")
+
+// ttlBackend is implemented by backends that can expire a value after a
+// duration instead of it living forever.
+type ttlBackend interface {
+	SetWithTTL(key, val []byte, ttl time.Duration) error
+}
+
+// gcBackend is implemented by backends that can reclaim space left behind
+// by overwritten or deleted keys.
+type gcBackend interface {
+	RunValueLogGC(discardRatio float64) error
+}
+
+// statsBackend is implemented by backends that expose storage-size and GC metrics.
+type statsBackend interface {
+	Stats() (Stats, error)
+}
+
+// Stats describes backend storage size and GC activity.
+type Stats struct {
+	LSMSize  int64
+	VLogSize int64
+	GCRuns   int64
+}
+
+// SetWithTTL stores val under key, expiring it after ttl. It returns
+// ErrTTLUnsupported if the backend doesn't support expiring values.
+func (s *Store) SetWithTTL(key, val []byte, ttl time.Duration) error {
+	tb, ok := s.backend.(ttlBackend)
+	if !ok {
+		return ErrTTLUnsupported
+	}
+	return tb.SetWithTTL(key, val, ttl)
+}
+
+// SetStringWithTTL stores val under key, expiring it after ttl.
+func (s *Store) SetStringWithTTL(key, val string, ttl time.Duration) error {
+	return s.SetWithTTL([]byte(key), []byte(val), ttl)
+}
+
+// StartGC runs the backend's value-log garbage collection every interval,
+// reclaiming space left behind by overwritten or deleted keys. It's a no-op
+// on backends that don't support GC (e.g. the in-memory and Redis backends).
+// Calling it again while a GC loop is already running is a no-op. StartGC
+// and StopGC may be called concurrently from multiple goroutines.
+func (s *Store) StartGC(interval time.Duration, discardRatio float64) {
+	gb, ok := s.backend.(gcBackend)
+	if !ok {
+		return
+	}
+
+	s.gcMu.Lock()
+	defer s.gcMu.Unlock()
+	if s.gcStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.gcStop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = gb.RunValueLogGC(discardRatio)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopGC stops a GC loop started with StartGC.
+func (s *Store) StopGC() {
+	s.gcMu.Lock()
+	defer s.gcMu.Unlock()
+	if s.gcStop != nil {
+		close(s.gcStop)
+		s.gcStop = nil
+	}
+}
+
+// Stats returns storage-size and GC metrics where the backend supports them.
+func (s *Store) Stats() (Stats, error) {
+	sb, ok := s.backend.(statsBackend)
+	if !ok {
+		return Stats{}, ErrStatsUnsupported
+	}
+	stats, err := sb.Stats()
+	if err != nil {
+		return Stats{}, serr.Wrap(err, "Error reading store stats")
+	}
+	return stats, nil
+}
@@ -0,0 +1,141 @@
+package robadger
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// IterOptions configures a Store.Iterator.
+type IterOptions struct {
+	Prefix []byte
+	// Reverse iterates keys in descending lexicographic order instead of
+	// ascending. On every backend (not just Badger's native cursor), keys
+	// are sorted before Reverse is applied, so ordering is well-defined
+	// regardless of which KVBackend is in use.
+	Reverse bool
+	// PrefetchSize hints how many values a native cursor should buffer
+	// ahead; backends without a native cursor ignore it.
+	PrefetchSize int
+	// KeysOnly skips reading values, for backends where that's cheaper.
+	KeysOnly bool
+}
+
+// DefaultIterOptions mirrors Badger's own default iterator options.
+var DefaultIterOptions = IterOptions{PrefetchSize: 100}
+
+// iteratorBackend is implemented by backends that can provide a native
+// streaming cursor. Backends that don't implement it get a buffered
+// fallback built on top of Iterate.
+type iteratorBackend interface {
+	NewIterator(opts IterOptions) *Iter
+}
+
+// Iter is a streaming cursor produced by Store.Iterator. Call Next before
+// the first Key/Value, and Close when done with it.
+type Iter struct {
+	nextFn  func() bool
+	keyFn   func() []byte
+	valFn   func() []byte
+	closeFn func()
+}
+
+// Next advances the iterator, returning false once it's exhausted.
+func (it *Iter) Next() bool { return it.nextFn() }
+
+// Key returns the current key.
+func (it *Iter) Key() []byte { return it.keyFn() }
+
+// Value returns the current value, or nil if the iterator was created with KeysOnly.
+func (it *Iter) Value() []byte { return it.valFn() }
+
+// Close releases any resources held by the iterator.
+func (it *Iter) Close() {
+	if it.closeFn != nil {
+		it.closeFn()
+	}
+}
+
+// Scan calls fn for every key/value pair whose key starts with prefix.
+func (s *Store) Scan(prefix []byte, fn func(k, v []byte) error) error {
+	return s.backend.Iterate(prefix, fn)
+}
+
+// Keys returns every key starting with prefix.
+func (s *Store) Keys(prefix []byte) ([][]byte, error) {
+	var keys [][]byte
+	err := s.backend.Iterate(prefix, func(k, v []byte) error {
+		keys = append(keys, append([]byte(nil), k...))
+		return nil
+	})
+	if err != nil {
+		return nil, serr.Wrap(err, "Error listing keys")
+	}
+	return keys, nil
+}
+
+// Iterator returns a streaming cursor over the store using opts. Backends
+// that expose a native cursor (e.g. Badger) stream directly from it;
+// others fall back to buffering the matching keys in memory.
+func (s *Store) Iterator(opts IterOptions) *Iter {
+	if ib, ok := s.backend.(iteratorBackend); ok {
+		return ib.NewIterator(opts)
+	}
+	return newBufferedIter(s, opts)
+}
+
+// newBufferedIter backs Store.Iterator for backends without a native
+// cursor (Mem, Redis). Those backends enumerate in unordered fashion (Go
+// map order, Redis SCAN order), so the buffered keys are sorted
+// lexicographically first - matching Badger's natural key order - before
+// Reverse is applied, rather than just reversing whatever arbitrary order
+// Iterate happened to produce.
+func newBufferedIter(s *Store, opts IterOptions) *Iter {
+	type pair struct{ k, v []byte }
+	var pairs []pair
+	_ = s.backend.Iterate(opts.Prefix, func(k, v []byte) error {
+		p := pair{k: append([]byte(nil), k...)}
+		if !opts.KeysOnly {
+			p.v = append([]byte(nil), v...)
+		}
+		pairs = append(pairs, p)
+		return nil
+	})
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].k, pairs[j].k) < 0
+	})
+	if opts.Reverse {
+		for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+			pairs[i], pairs[j] = pairs[j], pairs[i]
+		}
+	}
+
+	keys := make([][]byte, len(pairs))
+	vals := make([][]byte, len(pairs))
+	for i, p := range pairs {
+		keys[i] = p.k
+		vals[i] = p.v
+	}
+
+	idx := -1
+	return &Iter{
+		nextFn: func() bool {
+			idx++
+			return idx < len(keys)
+		},
+		keyFn: func() []byte {
+			if idx < 0 || idx >= len(keys) {
+				return nil
+			}
+			return keys[idx]
+		},
+		valFn: func() []byte {
+			if opts.KeysOnly || idx < 0 || idx >= len(vals) {
+				return nil
+			}
+			return vals[idx]
+		},
+	}
+}
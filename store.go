@@ -0,0 +1,146 @@
+package robadger
+
+import (
+	"sync"
+
+	"github.com/go-redis/redis"
+	"github.com/rohanthewiz/roencoding"
+)
+
+// Store is a key-value store sitting on top of a pluggable KVBackend. The
+// default backend is BadgerDB, but callers can swap in an in-memory or
+// Redis-backed store (or their own KVBackend) without changing any of the
+// methods below.
+type Store struct {
+	backend         KVBackend
+	maxAttempts     int
+	trackHashedKeys bool
+	gcMu            sync.Mutex
+	gcStop          chan struct{}
+}
+
+// hashedKeyRefPrefix namespaces the hash->original-key reverse mapping
+// written by TouchHashed when EnableHashedKeyTracking is on.
+const hashedKeyRefPrefix = "hashref:"
+
+// NewStore creates a new key-value store backed by BadgerDB.
+// If no directories are supplied, a temp directory will be created i.e. data is volatile!
+// If only one directory is supplied, it is used as the key and value stores
+// If more than 1 directory is supplied, the first will be used as the key store, and the second as the value store
+// It is the client's responsibility to Close() the store. Note that close also returns an err value
+// which should be checked, because some data is flushed on close. Example:
+//		defer func() {
+//			err = store.Close()
+//			if err != nil {
+//				Log("Error", "Error closing store - some values may not have been saved", "error", err.Error())
+//		}()
+func NewStore(dirs ...string) (*Store, error) {
+	return NewStoreWithBackend(NewBadgerBackend(dirs...))
+}
+
+// NewMemStore creates a new key-value store backed by an in-memory map.
+// Data does not survive Close() and is never written to disk - handy for
+// tests and other ephemeral contexts.
+func NewMemStore() (*Store, error) {
+	return NewStoreWithBackend(NewMemBackend())
+}
+
+// NewRedisStore creates a new key-value store backed by a Redis server,
+// useful in distributed deployments where a shared cache is preferable to
+// a local file store.
+func NewRedisStore(opt *redis.Options) (*Store, error) {
+	return NewStoreWithBackend(NewRedisBackend(opt))
+}
+
+// NewStoreWithBackend creates a Store on top of an already-constructed
+// KVBackend, allowing callers to supply their own implementation.
+func NewStoreWithBackend(backend KVBackend) (st *Store, err error) {
+	if err = backend.Open(); err != nil {
+		return nil, err
+	}
+	return &Store{backend: backend}, nil
+}
+
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+func (s *Store) SetString(key, val string) error {
+	return s.backend.Set([]byte(key), []byte(val))
+}
+
+// GetString returns ErrKeyNotFound if key is absent, regardless of backend.
+func (s *Store) GetString(key string) (out string, err error) {
+	val, err := s.backend.Get([]byte(key))
+	if err != nil {
+		return
+	}
+	out = string(val)
+	return
+}
+
+func (s *Store) SetBytes(k, v []byte) error {
+	return s.backend.Set(k, v)
+}
+
+// GetBytes returns ErrKeyNotFound if k is absent, regardless of backend.
+func (s *Store) GetBytes(k []byte) (out []byte, err error) {
+	return s.backend.Get(k)
+}
+
+// Exists reports whether key is present in the store.
+func (s *Store) Exists(key []byte) (bool, error) {
+	return s.backend.Exists(key)
+}
+
+// Delete removes key from the store.
+func (s *Store) Delete(key []byte) error {
+	return s.backend.Delete(key)
+}
+
+// EnableHashedKeyTracking turns on persisting a hash->original-key reverse
+// mapping whenever TouchHashed is called, so the original inputs can later
+// be enumerated with OriginalKeys.
+func (s *Store) EnableHashedKeyTracking(enabled bool) {
+	s.trackHashedKeys = enabled
+}
+
+// Add a hashed key to the store if it doesn't already exist
+func (s *Store) TouchHashed(in string) (err error) {
+	hash := roencoding.XXHash(in)
+	if err = s.backend.Touch([]byte(hash)); err != nil {
+		return
+	}
+	if s.trackHashedKeys {
+		err = s.backend.Set([]byte(hashedKeyRefPrefix+hash), []byte(in))
+	}
+	return
+}
+
+// Does hash of key exist in the store?
+func (s *Store) ExistsHashed(in string) (exists bool, err error) {
+	return s.backend.Exists([]byte(roencoding.XXHash(in)))
+}
+
+// OriginalKey returns the original input that was hashed to produce hash,
+// if hashed-key tracking was enabled via EnableHashedKeyTracking at the time.
+func (s *Store) OriginalKey(hash string) (string, error) {
+	val, err := s.backend.Get([]byte(hashedKeyRefPrefix + hash))
+	if err != nil {
+		return "", err
+	}
+	if len(val) == 0 {
+		return "", ErrKeyNotFound
+	}
+	return string(val), nil
+}
+
+// OriginalKeys returns every original input recorded via hashed-key tracking.
+func (s *Store) OriginalKeys() ([]string, error) {
+	var out []string
+	err := s.backend.Iterate([]byte(hashedKeyRefPrefix), func(k, v []byte) error {
+		out = append(out, string(v))
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,109 @@
+package robadger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMemIteratorOrderAndReverse(t *testing.T) {
+	s, err := NewMemStore()
+	if err != nil {
+		t.Fatalf("NewMemStore: %v", err)
+	}
+	defer s.Close()
+
+	for _, k := range []string{"b", "a", "c"} {
+		if err := s.SetString(k, k); err != nil {
+			t.Fatalf("SetString(%q): %v", k, err)
+		}
+	}
+
+	var forward []string
+	it := s.Iterator(IterOptions{})
+	for it.Next() {
+		forward = append(forward, string(it.Key()))
+	}
+	it.Close()
+	want := []string{"a", "b", "c"}
+	for i, k := range want {
+		if forward[i] != k {
+			t.Fatalf("forward order = %v, want %v", forward, want)
+		}
+	}
+
+	var reverse []string
+	it = s.Iterator(IterOptions{Reverse: true})
+	for it.Next() {
+		reverse = append(reverse, string(it.Key()))
+	}
+	it.Close()
+	wantRev := []string{"c", "b", "a"}
+	for i, k := range wantRev {
+		if reverse[i] != k {
+			t.Fatalf("reverse order = %v, want %v", reverse, wantRev)
+		}
+	}
+}
+
+func TestMemTTLExpiryAndTouchRevives(t *testing.T) {
+	s, err := NewMemStore()
+	if err != nil {
+		t.Fatalf("NewMemStore: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.SetWithTTL([]byte("k"), []byte("v"), 10*time.Millisecond); err != nil {
+		t.Fatalf("SetWithTTL: %v", err)
+	}
+	if val, err := s.GetBytes([]byte("k")); err != nil || !bytes.Equal(val, []byte("v")) {
+		t.Fatalf("GetBytes before expiry = %q, %v", val, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := s.GetBytes([]byte("k")); err != ErrKeyNotFound {
+		t.Fatalf("GetBytes after expiry = %v, want ErrKeyNotFound", err)
+	}
+
+	if err := s.backend.Touch([]byte("k")); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	exists, err := s.Exists([]byte("k"))
+	if err != nil || !exists {
+		t.Fatalf("Exists after Touch = %v, %v, want true", exists, err)
+	}
+}
+
+func TestMemUpdateCommitsAndViewIsReadOnly(t *testing.T) {
+	s, err := NewMemStore()
+	if err != nil {
+		t.Fatalf("NewMemStore: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Update(func(tx *Tx) error {
+		return tx.Set([]byte("k"), []byte("v1"))
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if err := s.View(func(tx *Tx) error {
+		val, err := tx.Get([]byte("k"))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(val, []byte("v1")) {
+			t.Fatalf("View saw %q, want v1", val)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("View: %v", err)
+	}
+
+	if err := s.View(func(tx *Tx) error {
+		return tx.Set([]byte("k"), []byte("v2"))
+	}); err != errReadOnlyTx {
+		t.Fatalf("write in View = %v, want errReadOnlyTx", err)
+	}
+}
@@ -0,0 +1,226 @@
+package robadger
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rohanthewiz/roencoding"
+	"github.com/rohanthewiz/serr"
+)
+
+// DefaultMaxTxAttempts is the number of attempts, the first one included,
+// that Update makes at a transaction that fails with ErrConflict before
+// giving up.
+const DefaultMaxTxAttempts = 5
+
+// errReadOnlyTx is returned by Tx.Set/Delete when called from a View.
+var errReadOnlyTx = errors.New("robadger: write attempted on a read-only transaction (use Update instead of View)")
+
+// txOps is what a Tx delegates its reads and writes to. badgerBackend
+// supplies one backed by a real *badger.Txn, giving Update/View genuine
+// snapshot isolation and conflict detection. Backends without a native
+// transaction fall back to stagedTxOps, which has neither - see its doc
+// comment.
+type txOps interface {
+	Get(key []byte) ([]byte, error)
+	Set(key, val []byte) error
+	Delete(key []byte) error
+	Exists(key []byte) (bool, error)
+}
+
+// txnBackend is implemented by backends that can run Store.Update/View
+// against their own native transaction instead of Store's staged-write
+// fallback. Only badgerBackend implements it today.
+type txnBackend interface {
+	runUpdate(maxAttempts int, fn func(tx *Tx) error) error
+	runView(fn func(tx *Tx) error) error
+}
+
+// Tx is a set of reads and writes against a Store, run inside Update or View.
+type Tx struct {
+	ops      txOps
+	readOnly bool
+}
+
+// Get reads key, seeing any writes already made on this Tx.
+func (t *Tx) Get(key []byte) ([]byte, error) {
+	return t.ops.Get(key)
+}
+
+// Set writes key, visible to later reads on this Tx immediately, and to
+// everyone else once the enclosing Update commits.
+func (t *Tx) Set(key, val []byte) error {
+	if t.readOnly {
+		return errReadOnlyTx
+	}
+	return t.ops.Set(key, val)
+}
+
+// Delete removes key, visible to later reads on this Tx immediately, and to
+// everyone else once the enclosing Update commits.
+func (t *Tx) Delete(key []byte) error {
+	if t.readOnly {
+		return errReadOnlyTx
+	}
+	return t.ops.Delete(key)
+}
+
+// Exists reports whether key is present, seeing any writes already made on this Tx.
+func (t *Tx) Exists(key []byte) (bool, error) {
+	return t.ops.Exists(key)
+}
+
+// TouchHashed writes a hashed key if it doesn't already exist.
+func (t *Tx) TouchHashed(in string) error {
+	key := []byte(roencoding.XXHash(in))
+	exists, err := t.Exists(key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return t.Set(key, []byte{})
+}
+
+// stagedTxOps buffers writes in a plain map and applies them via the
+// backend's Batch when the transaction commits. Unlike a real backend
+// transaction, it gives no snapshot isolation against concurrent
+// transactions and no conflict detection of its own - a retry only happens
+// if the underlying Batch itself reports ErrConflict (Redis does, via
+// WATCH/MULTI; the in-memory backend never does, since its Batch is a
+// single critical section).
+type stagedTxOps struct {
+	backend KVBackend
+	writes  map[string][]byte
+	deletes map[string]bool
+}
+
+func newStagedTxOps(backend KVBackend) *stagedTxOps {
+	return &stagedTxOps{
+		backend: backend,
+		writes:  make(map[string][]byte),
+		deletes: make(map[string]bool),
+	}
+}
+
+func (o *stagedTxOps) Get(key []byte) ([]byte, error) {
+	k := string(key)
+	if o.deletes[k] {
+		return nil, ErrKeyNotFound
+	}
+	if v, ok := o.writes[k]; ok {
+		return v, nil
+	}
+	return o.backend.Get(key)
+}
+
+func (o *stagedTxOps) Set(key, val []byte) error {
+	k := string(key)
+	delete(o.deletes, k)
+	o.writes[k] = append([]byte(nil), val...)
+	return nil
+}
+
+func (o *stagedTxOps) Delete(key []byte) error {
+	k := string(key)
+	delete(o.writes, k)
+	o.deletes[k] = true
+	return nil
+}
+
+func (o *stagedTxOps) Exists(key []byte) (bool, error) {
+	k := string(key)
+	if o.deletes[k] {
+		return false, nil
+	}
+	if _, ok := o.writes[k]; ok {
+		return true, nil
+	}
+	return o.backend.Exists(key)
+}
+
+func (o *stagedTxOps) entries() []Entry {
+	entries := make([]Entry, 0, len(o.writes))
+	for k, v := range o.writes {
+		entries = append(entries, Entry{Key: []byte(k), Val: v})
+	}
+	return entries
+}
+
+func (o *stagedTxOps) commit() error {
+	for k := range o.deletes {
+		if err := o.backend.Delete([]byte(k)); err != nil {
+			return serr.Wrap(err, "Error deleting key during transaction commit")
+		}
+	}
+	if len(o.writes) == 0 {
+		return nil
+	}
+	return o.backend.Batch(o.entries())
+}
+
+// Update runs fn against a writable Tx and commits its writes when fn
+// returns nil. On backends that implement txnBackend (Badger), fn runs
+// against a real backend transaction: it sees a consistent snapshot and a
+// commit-time write-write conflict retries fn from scratch, with
+// exponential backoff, for up to maxTxAttempts attempts in all. On other backends, Update
+// falls back to stagedTxOps - see its doc comment for what that does and
+// doesn't guarantee. Either way, ErrConflict is returned unwrapped if every
+// attempt is exhausted, so callers can compare against it directly.
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	if tb, ok := s.backend.(txnBackend); ok {
+		return tb.runUpdate(s.maxTxAttempts(), fn)
+	}
+	return s.fallbackUpdate(fn)
+}
+
+func (s *Store) fallbackUpdate(fn func(tx *Tx) error) error {
+	backoff := 10 * time.Millisecond
+	var err error
+
+	for attempt := 0; attempt < s.maxTxAttempts(); attempt++ {
+		ops := newStagedTxOps(s.backend)
+		tx := &Tx{ops: ops}
+		if err = fn(tx); err != nil {
+			return err
+		}
+		err = ops.commit()
+		if err != ErrConflict {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return ErrConflict
+}
+
+// View runs fn against a read-only Tx. On backends that implement
+// txnBackend (Badger), fn sees a consistent point-in-time snapshot. Calling
+// Set or Delete on the Tx returns errReadOnlyTx.
+func (s *Store) View(fn func(tx *Tx) error) error {
+	if tb, ok := s.backend.(txnBackend); ok {
+		return tb.runView(fn)
+	}
+	return fn(&Tx{ops: newStagedTxOps(s.backend), readOnly: true})
+}
+
+// SetMaxTxAttempts overrides the number of attempts, the first one included,
+// that Update makes at a transaction that fails with ErrConflict. The
+// default is DefaultMaxTxAttempts.
+func (s *Store) SetMaxTxAttempts(n int) {
+	s.maxAttempts = n
+}
+
+func (s *Store) maxTxAttempts() int {
+	if s.maxAttempts > 0 {
+		return s.maxAttempts
+	}
+	return DefaultMaxTxAttempts
+}
+
+// Batch writes all entries via a single backend operation, for bulk-load
+// throughput where Update's per-key staging overhead isn't needed.
+func (s *Store) Batch(entries []Entry) error {
+	return s.backend.Batch(entries)
+}